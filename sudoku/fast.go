@@ -0,0 +1,199 @@
+package sudoku
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// candidateSet is a bitmask of the digits (1..dim) a cell could still take,
+// bit i set meaning digit i is a candidate. It backs SolvePuzzleFast,
+// trading the clarity of the map[int]bool constraint type for the raw
+// speed of bitwise ops - no map allocation or hashing per cell.
+type candidateSet uint16
+
+const fullCandidateSet = candidateSet((1 << (dim + 1)) - 2) // bits 1..dim set
+
+func newCandidateSet(val int) candidateSet {
+	if val != 0 {
+		return 1 << uint(val)
+	}
+	return fullCandidateSet
+}
+
+func (c candidateSet) popcount() int {
+	return bits.OnesCount16(uint16(c))
+}
+
+func (c candidateSet) has(digit int) bool {
+	return c&(1<<uint(digit)) != 0
+}
+
+// clear removes digit from the set, reporting whether it was present.
+func (c *candidateSet) clear(digit int) bool {
+	if !c.has(digit) {
+		return false
+	}
+	*c &^= 1 << uint(digit)
+	return true
+}
+
+// SolvePuzzleFast solves a sudoku using a uint16 bitmask candidate
+// representation instead of map[int]bool. It behaves identically to
+// SolvePuzzle - same input/output shape, same propagate-then-search
+// strategy - but avoids a map allocation per cell, which dominates on
+// puzzles this small.
+func SolvePuzzleFast(puzzle []int) ([]int, bool) {
+	board := puzzle2FastBoard(puzzle)
+	final, solved := solveFastBySearch(board)
+	return fastBoard2Puzzle(final), solved
+}
+
+// SolvePuzzleCompat is the map[int]bool-based solver, kept under this
+// explicit name now that SolvePuzzleFast exists. It is the same as
+// SolvePuzzle, which runs the map-based classic 9x9 Puzzle solver.
+func SolvePuzzleCompat(puzzle []int) ([]int, bool) {
+	return SolvePuzzle(puzzle)
+}
+
+func solveFastBySearch(board []candidateSet) ([]candidateSet, bool) {
+	changes := 0
+	for changed := true; changed; changed = changes > 0 {
+		changes = applyAllFastConstraints(board)
+	}
+	complete, err := checkFastCompletion(board)
+	if err != nil {
+		return board, false
+	}
+	if complete {
+		return board, true
+	}
+
+	candidate, err := getFastSearchCandidate(board)
+	if err != nil {
+		return board, false
+	}
+	for digit := 1; digit <= dim; digit++ {
+		if !board[candidate].has(digit) {
+			continue
+		}
+		clone := cloneFastBoard(board)
+		clone[candidate] = newCandidateSet(digit)
+		clone, solved := solveFastBySearch(clone)
+		if solved {
+			return clone, true
+		}
+	}
+	return board, false
+}
+
+// getFastSearchCandidate finds the best candidate to use for brute force
+// search, favoring more restrictive cells first.
+func getFastSearchCandidate(board []candidateSet) (int, error) {
+	for i := 2; i <= dim; i++ {
+		for j, c := range board {
+			if c.popcount() == i {
+				return j, nil
+			}
+		}
+	}
+	return 0, errors.New("no search candidates could be found")
+}
+
+func cloneFastBoard(board []candidateSet) []candidateSet {
+	cloned := make([]candidateSet, len(board))
+	copy(cloned, board)
+	return cloned
+}
+
+func checkFastCompletion(board []candidateSet) (bool, error) {
+	complete := true
+	for _, c := range board {
+		switch c.popcount() {
+		case 0:
+			return false, errors.New("invalid sudoku")
+		case 1:
+			// pinned, nothing to do
+		default:
+			complete = false
+		}
+	}
+	return complete, nil
+}
+
+// applyAllFastConstraints sweeps every row, column and box sequentially.
+// For 27 small masks the channel/goroutine fan-out the map-based solver
+// uses costs more than it saves; parallelism is better spent at the
+// puzzle level, as BenchmarkParallel already does for SolvePuzzle.
+func applyAllFastConstraints(board []candidateSet) int {
+	changes := 0
+	for _, category := range masks {
+		for _, mask := range category {
+			changes += propagateFastConstraint1(board, mask) + propagateFastConstraint2(board, mask)
+		}
+	}
+	return changes
+}
+
+// propagateFastConstraint1 is propagateConstraint1 rewritten over
+// candidateSet: a pinned cell's digit cannot appear anywhere else in the
+// mask.
+func propagateFastConstraint1(board []candidateSet, boardMask []int) int {
+	changes := 0
+	for _, cur := range boardMask {
+		if board[cur].popcount() != 1 {
+			continue
+		}
+		digit := bits.TrailingZeros16(uint16(board[cur]))
+		for _, elem := range boardMask {
+			if elem == cur {
+				continue
+			}
+			if board[elem].clear(digit) {
+				changes++
+			}
+		}
+	}
+	return changes
+}
+
+// propagateFastConstraint2 is propagateConstraint2 rewritten over
+// candidateSet: a digit confined to one cell in the mask must go there.
+func propagateFastConstraint2(board []candidateSet, boardMask []int) int {
+	changes := 0
+
+Outer:
+	for digit := 1; digit <= dim; digit++ {
+		found := -1
+		for _, cell := range boardMask {
+			if board[cell].has(digit) {
+				if found != -1 || board[cell].popcount() == 1 {
+					continue Outer
+				}
+				found = cell
+			}
+		}
+		if found >= 0 {
+			changes += board[found].popcount() - 1
+			board[found] = newCandidateSet(digit)
+		}
+	}
+	return changes
+}
+
+func puzzle2FastBoard(puzzle []int) []candidateSet {
+	board := make([]candidateSet, len(puzzle))
+	for i, val := range puzzle {
+		board[i] = newCandidateSet(val)
+	}
+	return board
+}
+
+func fastBoard2Puzzle(board []candidateSet) []int {
+	puzzle := make([]int, len(board))
+	for i, c := range board {
+		if c.popcount() == 1 {
+			puzzle[i] = bits.TrailingZeros16(uint16(c))
+		}
+	}
+	return puzzle
+}
@@ -0,0 +1,108 @@
+package sudoku
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Generate produces a well-formed 9x9 puzzle with a unique solution, rated
+// at or below the requested target Difficulty, seeded for reproducible
+// output. It starts from a random full solution, then removes clues in
+// random order, checking after each removal that the puzzle still has
+// exactly one solution and that it can still be rated within target using
+// SolveWithStrategies. Removal stops once every cell has been tried.
+func Generate(seed int64, target Difficulty) ([]int, error) {
+	rng := rand.New(rand.NewSource(seed))
+
+	solution, err := generateSolvedGrid(rng)
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle := append([]int{}, solution...)
+	for _, cell := range rng.Perm(len(puzzle)) {
+		removed := puzzle[cell]
+		if removed == 0 {
+			continue
+		}
+		puzzle[cell] = 0
+
+		if CountSolutions(puzzle, 2) != 1 {
+			puzzle[cell] = removed
+			continue
+		}
+
+		_, _, rating, ok := SolveWithStrategies(puzzle)
+		if !ok || rating == Unrated || rating > target {
+			puzzle[cell] = removed
+		}
+	}
+
+	return puzzle, nil
+}
+
+// CountSolutions counts how many distinct solutions a puzzle has, stopping
+// early once limit is reached. It shares solveBySearch's propagate-then-
+// search strategy but explores every branch instead of stopping at the
+// first solution, which also makes it useful on its own for validating
+// hand-built puzzles.
+func CountSolutions(puzzle []int, limit int) int {
+	return countSolutions(puzzle2Constraints(puzzle), limit)
+}
+
+func countSolutions(constraints []constraint, limit int) int {
+	changes := 0
+	for changed := true; changed; changed = changes > 0 {
+		changes = applyAllConstraints(constraints)
+	}
+	complete, err := checkCompletion(constraints)
+	if err != nil {
+		return 0
+	}
+	if complete {
+		return 1
+	}
+
+	candidate, err := getSearchCandidate(constraints)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for key := range constraints[candidate] {
+		clone := cloneBoard(constraints)
+		clone[candidate] = newConstraint(key)
+		count += countSolutions(clone, limit-count)
+		if count >= limit {
+			break
+		}
+	}
+	return count
+}
+
+// generateSolvedGrid builds a random complete solution by filling the
+// independent diagonal boxes with random permutations, then solving the
+// rest to completion.
+func generateSolvedGrid(rng *rand.Rand) ([]int, error) {
+	puzzle := make([]int, dim*dim)
+
+	digits := make([]int, dim)
+	for i := range digits {
+		digits[i] = i + 1
+	}
+
+	for r := 0; r < boxDim; r++ {
+		box := masks[2][r*(boxDim+1)]
+		perm := append([]int{}, digits...)
+		rng.Shuffle(len(perm), func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+		for i, cell := range box {
+			puzzle[cell] = perm[i]
+		}
+	}
+
+	solved, ok := solveBySearch(puzzle2Constraints(puzzle))
+	if !ok {
+		return nil, errors.New("could not generate a solved grid")
+	}
+	return constraints2Puzzle(solved), nil
+}
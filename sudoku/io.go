@@ -0,0 +1,328 @@
+package sudoku
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format parses and serializes puzzles in a particular sudoku text format.
+type Format interface {
+	Name() string
+	Decode(reader io.Reader) ([][]int, error)
+	Encode(writer io.Writer, puzzles [][]int) error
+}
+
+// The built-in Formats. Pass one to NewDecoder/NewEncoder to read or write
+// a known format directly instead of relying on auto-detection.
+var (
+	Euler96 Format = eulerFormat{}
+	SDM     Format = sdmFormat{}
+	SDK     Format = sdkFormat{}
+	Plain   Format = plainFormat{}
+	JSON    Format = jsonFormat{}
+)
+
+// Decoder reads sudokus from a reader. Its Format can be set explicitly
+// with NewDecoder; the zero value auto-detects the format from the input.
+type Decoder struct {
+	Format Format
+}
+
+// NewDecoder builds a Decoder that always reads the given format.
+func NewDecoder(format Format) *Decoder {
+	return &Decoder{Format: format}
+}
+
+// Decode reads every puzzle it can find in reader.
+func (d *Decoder) Decode(reader io.Reader) ([][]int, error) {
+	format := d.Format
+	if format == nil {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		format = detectFormat(data)
+		reader = bytes.NewReader(data)
+	}
+	return format.Decode(reader)
+}
+
+// Encoder writes sudokus in a given Format.
+type Encoder struct {
+	Format Format
+}
+
+// NewEncoder builds an Encoder that always writes the given format.
+func NewEncoder(format Format) *Encoder {
+	return &Encoder{Format: format}
+}
+
+// Encode writes puzzles to writer using the Encoder's Format.
+func (e *Encoder) Encode(writer io.Writer, puzzles [][]int) error {
+	return e.Format.Encode(writer, puzzles)
+}
+
+// detectFormat sniffs a format from its content: JSON starts with '{' or
+// '[', Euler96 mentions "Grid", SDK is '#'-prefixed metadata, and anything
+// else is treated as one 81-char digit line per puzzle.
+func detectFormat(data []byte) Format {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '['):
+		return JSON
+	case bytes.Contains(trimmed, []byte("Grid")):
+		return Euler96
+	case bytes.HasPrefix(trimmed, []byte("#")):
+		return SDK
+	default:
+		return SDM
+	}
+}
+
+// eulerFormat is the Project Euler 96 format: https://projecteuler.net/problem=96
+// "Grid NN" header lines followed by 9 lines of 9 digits, repeated.
+type eulerFormat struct{}
+
+func (eulerFormat) Name() string { return "euler96" }
+
+func (eulerFormat) Decode(reader io.Reader) ([][]int, error) {
+	var puzzles [][]int
+	var current []int
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), "Grid") {
+			continue
+		}
+		for _, runeValue := range scanner.Text() {
+			val := int(runeValue - '0')
+			if val > 9 || val < 0 {
+				return nil, errors.New("invalid character in puzzle " + strconv.Itoa(len(puzzles)) + " element " + strconv.Itoa(len(current)))
+			}
+			current = append(current, val)
+		}
+		if len(current) == dim*dim {
+			puzzles = append(puzzles, current)
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(current) != 0 {
+		return nil, errors.New("invalid puzzle dimensions")
+	}
+	return puzzles, nil
+}
+
+func (eulerFormat) Encode(writer io.Writer, puzzles [][]int) error {
+	for i, puzzle := range puzzles {
+		if _, err := fmt.Fprintf(writer, "Grid %02d\n", i+1); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, Puzzle2String(puzzle)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sdmFormat is one 81-char line per puzzle, "0" or "." for blanks.
+type sdmFormat struct{}
+
+func (sdmFormat) Name() string { return "sdm" }
+
+// Decode accumulates digit/blank characters across lines until it has a
+// full puzzle, so besides the usual one-line-per-puzzle layout it also
+// copes with a puzzle wrapped across several shorter lines.
+func (sdmFormat) Decode(reader io.Reader) ([][]int, error) {
+	var puzzles [][]int
+	var current []int
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		for _, r := range strings.TrimSpace(scanner.Text()) {
+			switch {
+			case r == '.':
+				current = append(current, 0)
+			case r >= '0' && r <= '9':
+				current = append(current, int(r-'0'))
+			default:
+				return nil, errors.New("invalid character in puzzle: " + string(r))
+			}
+		}
+		if len(current) == dim*dim {
+			puzzles = append(puzzles, current)
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(current) != 0 {
+		return nil, errors.New("invalid puzzle dimensions")
+	}
+	return puzzles, nil
+}
+
+func (sdmFormat) Encode(writer io.Writer, puzzles [][]int) error {
+	for _, puzzle := range puzzles {
+		if _, err := io.WriteString(writer, encodeDigitLine(puzzle)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeDigitLine(puzzle []int) string {
+	var b strings.Builder
+	for _, val := range puzzle {
+		b.WriteString(strconv.Itoa(val))
+	}
+	return b.String()
+}
+
+// plainFormat is a plain 81-char string per puzzle. It reads and writes
+// exactly as SDM does, and is kept as its own Format so callers can name
+// it explicitly.
+type plainFormat struct{}
+
+func (plainFormat) Name() string { return "plain" }
+
+func (plainFormat) Decode(reader io.Reader) ([][]int, error) {
+	return sdmFormat{}.Decode(reader)
+}
+
+func (plainFormat) Encode(writer io.Writer, puzzles [][]int) error {
+	return sdmFormat{}.Encode(writer, puzzles)
+}
+
+// sdkFormat is SadMan Sudoku's format: "#"-prefixed metadata lines
+// followed by dim lines of dim characters, "." for blanks.
+type sdkFormat struct{}
+
+func (sdkFormat) Name() string { return "sdk" }
+
+func (sdkFormat) Decode(reader io.Reader) ([][]int, error) {
+	var puzzles [][]int
+	var current []int
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(line) != dim {
+			return nil, errors.New("invalid sdk line length: " + strconv.Itoa(len(line)))
+		}
+		for _, r := range line {
+			switch {
+			case r == '.':
+				current = append(current, 0)
+			case r >= '1' && r <= '9':
+				current = append(current, int(r-'0'))
+			default:
+				return nil, errors.New("invalid character in sdk puzzle: " + string(r))
+			}
+		}
+		if len(current) == dim*dim {
+			puzzles = append(puzzles, current)
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return puzzles, nil
+}
+
+func (sdkFormat) Encode(writer io.Writer, puzzles [][]int) error {
+	for i, puzzle := range puzzles {
+		if _, err := fmt.Fprintf(writer, "#Puzzle %d\n", i+1); err != nil {
+			return err
+		}
+		for row := 0; row < dim; row++ {
+			var b strings.Builder
+			for col := 0; col < dim; col++ {
+				val := puzzle[row*dim+col]
+				if val == 0 {
+					b.WriteByte('.')
+				} else {
+					b.WriteString(strconv.Itoa(val))
+				}
+			}
+			if _, err := io.WriteString(writer, b.String()+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFormat decodes {"givens":[[0,0,3,...],...]} objects, either a single
+// one or a JSON array of them.
+type jsonFormat struct{}
+
+func (jsonFormat) Name() string { return "json" }
+
+type jsonPuzzle struct {
+	Givens [][]int `json:"givens"`
+}
+
+func (jsonFormat) Decode(reader io.Reader) ([][]int, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []jsonPuzzle
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &docs); err != nil {
+			return nil, err
+		}
+	} else {
+		var doc jsonPuzzle
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, err
+		}
+		docs = []jsonPuzzle{doc}
+	}
+
+	puzzles := make([][]int, 0, len(docs))
+	for _, doc := range docs {
+		var puzzle []int
+		for _, row := range doc.Givens {
+			puzzle = append(puzzle, row...)
+		}
+		if len(puzzle) != dim*dim {
+			return nil, errors.New("invalid puzzle dimensions in json input")
+		}
+		puzzles = append(puzzles, puzzle)
+	}
+	return puzzles, nil
+}
+
+func (jsonFormat) Encode(writer io.Writer, puzzles [][]int) error {
+	docs := make([]jsonPuzzle, len(puzzles))
+	for i, puzzle := range puzzles {
+		rows := make([][]int, dim)
+		for row := 0; row < dim; row++ {
+			rows[row] = append([]int{}, puzzle[row*dim:(row+1)*dim]...)
+		}
+		docs[i] = jsonPuzzle{Givens: rows}
+	}
+
+	encoder := json.NewEncoder(writer)
+	if len(docs) == 1 {
+		return encoder.Encode(docs[0])
+	}
+	return encoder.Encode(docs)
+}
@@ -0,0 +1,401 @@
+package sudoku
+
+import (
+	"errors"
+	"math"
+)
+
+// Constraint is a rule a sudoku variant enforces over a set of cells: which
+// cells it covers, how to propagate eliminations across them given the
+// current board, and how to check a finished assignment satisfies it.
+// Rows, columns, boxes, diagonals, jigsaw regions and killer cages are all
+// just different Constraint implementations over the same solver.
+type Constraint interface {
+	Cells() []int
+	Propagate(board []constraint) int
+	Validate(vals []int) bool
+}
+
+// UniqueRegion enforces that every cell it covers holds a distinct value.
+// It backs rows, columns and classic boxes, and is embedded by the other
+// region-shaped constraints below.
+type UniqueRegion struct {
+	cells []int
+}
+
+// NewUniqueRegion builds a UniqueRegion over the given cells.
+func NewUniqueRegion(cells []int) *UniqueRegion {
+	return &UniqueRegion{cells: cells}
+}
+
+func (r *UniqueRegion) Cells() []int { return r.cells }
+
+func (r *UniqueRegion) Propagate(board []constraint) int {
+	return propagateConstraint1(board, r.cells) + propagateKnownDigit(board, r.cells)
+}
+
+func (r *UniqueRegion) Validate(vals []int) bool {
+	return validateUnique(vals, r.cells)
+}
+
+// DiagonalUnique enforces the extra X-Sudoku rule that a main diagonal
+// also holds distinct values.
+type DiagonalUnique struct {
+	UniqueRegion
+}
+
+// NewDiagonalUnique builds a DiagonalUnique over the given diagonal cells.
+func NewDiagonalUnique(cells []int) *DiagonalUnique {
+	return &DiagonalUnique{UniqueRegion{cells: cells}}
+}
+
+// JigsawRegion enforces uniqueness across an arbitrary, caller-supplied
+// set of cells, used for the irregularly shaped boxes of jigsaw sudoku.
+type JigsawRegion struct {
+	UniqueRegion
+}
+
+// NewJigsawRegion builds a JigsawRegion over the given cells.
+func NewJigsawRegion(cells []int) *JigsawRegion {
+	return &JigsawRegion{UniqueRegion{cells: cells}}
+}
+
+// Cage describes one killer-sudoku cage: the cells it covers and the value
+// they must sum to.
+type Cage struct {
+	Cells []int
+	Sum   int
+}
+
+// KillerCage enforces that its cells hold distinct values summing to a
+// target, pruning candidates that can no longer reach that sum.
+type KillerCage struct {
+	cells []int
+	sum   int
+}
+
+// NewKillerCage builds a KillerCage from a Cage description.
+func NewKillerCage(cage Cage) *KillerCage {
+	return &KillerCage{cells: cage.Cells, sum: cage.Sum}
+}
+
+func (k *KillerCage) Cells() []int { return k.cells }
+
+func (k *KillerCage) Propagate(board []constraint) int {
+	changes := propagateConstraint1(board, k.cells)
+
+	fixedSum := 0
+	var unknown []int
+	for _, cell := range k.cells {
+		if len(board[cell]) == 1 {
+			for v := range board[cell] {
+				fixedSum += v
+			}
+		} else {
+			unknown = append(unknown, cell)
+		}
+	}
+	if len(unknown) == 0 {
+		return changes
+	}
+	remaining := k.sum - fixedSum
+
+	for _, cell := range unknown {
+		for v := range board[cell] {
+			min, max := cageRestRange(board, unknown, cell)
+			if v+min > remaining || v+max < remaining {
+				delete(board[cell], v)
+				changes++
+			}
+		}
+	}
+	return changes
+}
+
+// cageRestRange estimates the smallest and largest sum the other cells in
+// a cage could still contribute, ignoring exclusions already accounted for
+// by the candidate being tested.
+func cageRestRange(board []constraint, cells []int, exclude int) (int, int) {
+	min, max := 0, 0
+	for _, cell := range cells {
+		if cell == exclude {
+			continue
+		}
+		lo, hi := -1, -1
+		for v := range board[cell] {
+			if lo == -1 || v < lo {
+				lo = v
+			}
+			if hi == -1 || v > hi {
+				hi = v
+			}
+		}
+		min += lo
+		max += hi
+	}
+	return min, max
+}
+
+func (k *KillerCage) Validate(vals []int) bool {
+	if !validateUnique(vals, k.cells) {
+		return false
+	}
+	sum := 0
+	for _, cell := range k.cells {
+		if vals[cell] == 0 {
+			return true
+		}
+		sum += vals[cell]
+	}
+	return sum == k.sum
+}
+
+// propagateKnownDigit is propagateConstraint2 generalised to an arbitrary
+// digit range instead of the fixed classic dim, for use by variant
+// constraints whose puzzle side length isn't known at compile time.
+func propagateKnownDigit(constraints []constraint, cells []int) int {
+	changes := 0
+	seen := map[int]bool{}
+	for _, cell := range cells {
+		for key := range constraints[cell] {
+			seen[key] = true
+		}
+	}
+
+Outer:
+	for digit := range seen {
+		found := -1
+		for _, cell := range cells {
+			if constraints[cell][digit] {
+				if found != -1 || len(constraints[cell]) == 1 {
+					continue Outer
+				}
+				found = cell
+			}
+		}
+		if found >= 0 {
+			changes += len(constraints[found]) - 1
+			constraints[found] = constraint{digit: true}
+		}
+	}
+	return changes
+}
+
+// validateUnique checks for duplicate non-zero values among cells.
+func validateUnique(vals []int, cells []int) bool {
+	seen := map[int]bool{}
+	for _, cell := range cells {
+		v := vals[cell]
+		if v != 0 && seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+// Puzzle is a sudoku variant: a grid of the given side length governed by
+// an arbitrary set of Constraints.
+type Puzzle struct {
+	Side        int
+	Constraints []Constraint
+}
+
+// NewClassic builds the standard row/column/box rules for an n x n grid.
+// n must be a perfect square (4, 9, 16, ...).
+func NewClassic(n int) *Puzzle {
+	return &Puzzle{Side: n, Constraints: classicRegions(n)}
+}
+
+// NewX builds the classic rules plus the two main diagonals, for
+// X-Sudoku.
+func NewX(n int) *Puzzle {
+	regions := classicRegions(n)
+	regions = append(regions,
+		NewDiagonalUnique(diagonalCells(n, true)),
+		NewDiagonalUnique(diagonalCells(n, false)))
+	return &Puzzle{Side: n, Constraints: regions}
+}
+
+// NewJigsaw builds row/column rules plus the caller-supplied irregular box
+// regions. The grid side length is taken from the number of regions, one
+// box per row.
+func NewJigsaw(regions [][]int) *Puzzle {
+	n := len(regions)
+	constraints := lineRegions(n)
+	for _, region := range regions {
+		constraints = append(constraints, NewJigsawRegion(region))
+	}
+	return &Puzzle{Side: n, Constraints: constraints}
+}
+
+// NewKiller builds the classic 9x9 rules plus the given killer cages.
+func NewKiller(cages []Cage) *Puzzle {
+	constraints := classicRegions(dim)
+	for _, cage := range cages {
+		constraints = append(constraints, NewKillerCage(cage))
+	}
+	return &Puzzle{Side: dim, Constraints: constraints}
+}
+
+func lineRegions(n int) []Constraint {
+	rows, cols := rowsAndCols(n)
+	var regions []Constraint
+	for _, row := range rows {
+		regions = append(regions, NewUniqueRegion(row))
+	}
+	for _, col := range cols {
+		regions = append(regions, NewUniqueRegion(col))
+	}
+	return regions
+}
+
+func classicRegions(n int) []Constraint {
+	regions := lineRegions(n)
+	for _, box := range boxCells(n) {
+		regions = append(regions, NewUniqueRegion(box))
+	}
+	return regions
+}
+
+func rowsAndCols(n int) ([][]int, [][]int) {
+	rows := make([][]int, n)
+	cols := make([][]int, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			rows[i] = append(rows[i], j+i*n)
+			cols[i] = append(cols[i], j*n+i)
+		}
+	}
+	return rows, cols
+}
+
+func boxCells(n int) [][]int {
+	boxN := int(math.Sqrt(float64(n)))
+	boxes := make([][]int, n)
+	offset := 0
+	for i := 0; i < n; i++ {
+		for j := 0; j < boxN; j++ {
+			for k := 0; k < boxN; k++ {
+				boxes[i] = append(boxes[i], j+
+					(k*n)+
+					(i*boxN)+
+					(offset*n*(boxN-1)))
+			}
+		}
+		if (i+1)%boxN == 0 {
+			offset++
+		}
+	}
+	return boxes
+}
+
+func diagonalCells(n int, primary bool) []int {
+	cells := make([]int, n)
+	for i := 0; i < n; i++ {
+		if primary {
+			cells[i] = i*n + i
+		} else {
+			cells[i] = i*n + (n - 1 - i)
+		}
+	}
+	return cells
+}
+
+// Solve attempts to solve the puzzle, propagating constraints and falling
+// back to brute-force search.
+func (p *Puzzle) Solve(puzzle []int) ([]int, bool) {
+	constraints := puzzleConstraints(puzzle, p.Side)
+	final, solved := p.solveBySearch(constraints)
+	return constraints2Puzzle(final), solved
+}
+
+func (p *Puzzle) solveBySearch(constraints []constraint) ([]constraint, bool) {
+	changes := 0
+	for changed := true; changed; changed = changes > 0 {
+		changes = p.propagate(constraints)
+	}
+	complete, err := checkCompletion(constraints)
+	if err != nil {
+		return constraints, false
+	}
+	if complete {
+		return constraints, true
+	}
+
+	candidate, err := p.getSearchCandidate(constraints)
+	if err != nil {
+		return constraints, false
+	}
+	for key := range constraints[candidate] {
+		clone := cloneBoard(constraints)
+		clone[candidate] = newConstraintN(key, p.Side)
+		clone, solved := p.solveBySearch(clone)
+		if solved {
+			return clone, true
+		}
+	}
+	return constraints, false
+}
+
+// propagate applies every constraint once and returns the number of
+// candidates eliminated. Variant constraints can overlap arbitrarily (a
+// killer cage and a row can share cells), so unlike the classic solver
+// this sweep is sequential rather than fanned out per region.
+func (p *Puzzle) propagate(constraints []constraint) int {
+	changes := 0
+	for _, c := range p.Constraints {
+		changes += c.Propagate(constraints)
+	}
+	return changes
+}
+
+func (p *Puzzle) getSearchCandidate(constraints []constraint) (int, error) {
+	for i := 2; i <= p.Side; i++ {
+		for j, elem := range constraints {
+			if len(elem) == i {
+				return j, nil
+			}
+		}
+	}
+	return 0, errors.New("no search candidates could be found")
+}
+
+// Validate determines if a given assignment satisfies every constraint in
+// the puzzle.
+func (p *Puzzle) Validate(vals []int) bool {
+	if len(vals) != p.Side*p.Side {
+		return false
+	}
+	for _, v := range vals {
+		if v < 0 || v > p.Side {
+			return false
+		}
+	}
+	for _, c := range p.Constraints {
+		if !c.Validate(vals) {
+			return false
+		}
+	}
+	return true
+}
+
+func puzzleConstraints(vals []int, n int) []constraint {
+	constraints := make([]constraint, len(vals))
+	for i, val := range vals {
+		constraints[i] = newConstraintN(val, n)
+	}
+	return constraints
+}
+
+func newConstraintN(val, n int) constraint {
+	c := constraint{}
+	if val != 0 {
+		c[val] = true
+	} else {
+		for i := 1; i <= n; i++ {
+			c[i] = true
+		}
+	}
+	return c
+}
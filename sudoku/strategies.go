@@ -0,0 +1,485 @@
+package sudoku
+
+// Difficulty rates how hard a puzzle is to solve using named logical
+// strategies. It is derived from the hardest technique SolveWithStrategies
+// had to apply, so it only rates puzzles that are solvable without guessing.
+type Difficulty int
+
+const (
+	Unrated Difficulty = iota
+	Easy
+	Medium
+	Hard
+	Expert
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "Easy"
+	case Medium:
+		return "Medium"
+	case Hard:
+		return "Hard"
+	case Expert:
+		return "Expert"
+	default:
+		return "Unrated"
+	}
+}
+
+// Step records a single deduction made by SolveWithStrategies: the
+// technique that was applied, the cells it reasoned about, and the
+// candidates it eliminated keyed by digit.
+type Step struct {
+	Technique  string
+	Cells      []int
+	Eliminated map[int][]int
+}
+
+// techniqueDifficulty ranks each named strategy by how hard it is to spot.
+var techniqueDifficulty = map[string]Difficulty{
+	"Naked Single":       Easy,
+	"Hidden Single":      Easy,
+	"Naked Pair":         Medium,
+	"Hidden Pair":        Medium,
+	"Pointing Pair":      Medium,
+	"Box/Line Reduction": Medium,
+	"Naked Triple":       Hard,
+	"X-Wing":             Hard,
+	"Swordfish":          Expert,
+}
+
+// strategies are tried in order, easiest first, so the recorded steps and
+// the resulting difficulty rating reflect the simplest valid solve path.
+var strategies = []func([]constraint) (Step, bool){
+	findNakedSingle,
+	findHiddenSingle,
+	findNakedN(2, "Naked Pair"),
+	findHiddenN(2, "Hidden Pair"),
+	findPointingPair,
+	findBoxLineReduction,
+	findNakedN(3, "Naked Triple"),
+	findFish(2, "X-Wing"),
+	findFish(3, "Swordfish"),
+}
+
+// SolveWithStrategies solves a puzzle the way a human would: by repeatedly
+// applying named logical deduction rules (naked/hidden singles and pairs,
+// pointing pairs, box/line reduction, X-Wing, Swordfish, ...) and recording
+// each one as a Step. If the rules stall before the puzzle is complete it
+// falls back to solveBySearch, in which case the puzzle is Unrated since
+// rating only makes sense for guessing-free solves.
+func SolveWithStrategies(puzzle []int) ([]int, []Step, Difficulty, bool) {
+	constraints := puzzle2Constraints(puzzle)
+	var steps []Step
+	rating := Unrated
+
+	for {
+		complete, err := checkCompletion(constraints)
+		if err != nil {
+			return constraints2Puzzle(constraints), steps, Unrated, false
+		}
+		if complete {
+			return constraints2Puzzle(constraints), steps, rating, true
+		}
+
+		step, ok := applyNextStrategy(constraints)
+		if !ok {
+			break
+		}
+		steps = append(steps, step)
+		if d := techniqueDifficulty[step.Technique]; d > rating {
+			rating = d
+		}
+	}
+
+	final, solved := solveBySearch(constraints)
+	return constraints2Puzzle(final), steps, Unrated, solved
+}
+
+// applyNextStrategy tries each technique, easiest first, and applies the
+// first deduction it finds.
+func applyNextStrategy(constraints []constraint) (Step, bool) {
+	for _, strategy := range strategies {
+		if step, ok := strategy(constraints); ok {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+// findNakedSingle looks for a cell already pinned to a single digit and
+// eliminates that digit from the rest of its row, column and box.
+func findNakedSingle(constraints []constraint) (Step, bool) {
+	for cell, c := range constraints {
+		if len(c) != 1 {
+			continue
+		}
+		var digit int
+		for key := range c {
+			digit = key
+		}
+
+		eliminated := map[int][]int{}
+		for _, maskType := range masks {
+			for _, mask := range maskType {
+				if !containsInt(mask, cell) {
+					continue
+				}
+				for _, elem := range mask {
+					if elem == cell || !constraints[elem][digit] {
+						continue
+					}
+					delete(constraints[elem], digit)
+					eliminated[digit] = append(eliminated[digit], elem)
+				}
+			}
+		}
+		if len(eliminated) == 0 {
+			continue
+		}
+		return Step{Technique: "Naked Single", Cells: []int{cell}, Eliminated: eliminated}, true
+	}
+	return Step{}, false
+}
+
+// findHiddenSingle looks for a digit confined to a single cell within a
+// row, column or box and pins that cell to it.
+func findHiddenSingle(constraints []constraint) (Step, bool) {
+	for _, maskType := range masks {
+		for _, mask := range maskType {
+			for digit := 1; digit <= dim; digit++ {
+				found := -1
+				ambiguous := false
+				for _, cell := range mask {
+					if constraints[cell][digit] {
+						if found != -1 {
+							ambiguous = true
+							break
+						}
+						found = cell
+					}
+				}
+				if ambiguous || found == -1 || len(constraints[found]) == 1 {
+					continue
+				}
+
+				eliminated := map[int][]int{}
+				for key := range constraints[found] {
+					if key != digit {
+						eliminated[key] = []int{found}
+					}
+				}
+				constraints[found] = newConstraint(digit)
+				return Step{Technique: "Hidden Single", Cells: []int{found}, Eliminated: eliminated}, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findNakedN returns a strategy that looks for n cells within a mask whose
+// combined candidates number exactly n, and eliminates those candidates
+// from the rest of the mask.
+func findNakedN(n int, name string) func([]constraint) (Step, bool) {
+	return func(constraints []constraint) (Step, bool) {
+		for _, maskType := range masks {
+			for _, mask := range maskType {
+				var candidates []int
+				for _, cell := range mask {
+					if len(constraints[cell]) >= 2 && len(constraints[cell]) <= n {
+						candidates = append(candidates, cell)
+					}
+				}
+
+				for _, combo := range combinations(candidates, n) {
+					union := map[int]bool{}
+					for _, cell := range combo {
+						for key := range constraints[cell] {
+							union[key] = true
+						}
+					}
+					if len(union) != n {
+						continue
+					}
+
+					eliminated := map[int][]int{}
+					for _, cell := range mask {
+						if containsInt(combo, cell) {
+							continue
+						}
+						for key := range union {
+							if constraints[cell][key] {
+								delete(constraints[cell], key)
+								eliminated[key] = append(eliminated[key], cell)
+							}
+						}
+					}
+					if len(eliminated) == 0 {
+						continue
+					}
+					return Step{Technique: name, Cells: combo, Eliminated: eliminated}, true
+				}
+			}
+		}
+		return Step{}, false
+	}
+}
+
+// findHiddenN returns a strategy that looks for n digits confined to the
+// same n cells within a mask, and strips every other candidate from those
+// cells.
+func findHiddenN(n int, name string) func([]constraint) (Step, bool) {
+	return func(constraints []constraint) (Step, bool) {
+		for _, maskType := range masks {
+			for _, mask := range maskType {
+				digitCells := map[int][]int{}
+				var digits []int
+				for digit := 1; digit <= dim; digit++ {
+					for _, cell := range mask {
+						if constraints[cell][digit] {
+							digitCells[digit] = append(digitCells[digit], cell)
+						}
+					}
+					if count := len(digitCells[digit]); count >= 1 && count <= n {
+						digits = append(digits, digit)
+					}
+				}
+
+				for _, combo := range combinations(digits, n) {
+					cellUnion := map[int]bool{}
+					for _, digit := range combo {
+						for _, cell := range digitCells[digit] {
+							cellUnion[cell] = true
+						}
+					}
+					if len(cellUnion) != n {
+						continue
+					}
+
+					eliminated := map[int][]int{}
+					var cells []int
+					for cell := range cellUnion {
+						cells = append(cells, cell)
+						for key := range constraints[cell] {
+							if !containsInt(combo, key) {
+								delete(constraints[cell], key)
+								eliminated[key] = append(eliminated[key], cell)
+							}
+						}
+					}
+					if len(eliminated) == 0 {
+						continue
+					}
+					return Step{Technique: name, Cells: cells, Eliminated: eliminated}, true
+				}
+			}
+		}
+		return Step{}, false
+	}
+}
+
+// findPointingPair looks for a digit within a box confined to a single row
+// or column, and eliminates it from the rest of that row/column outside
+// the box.
+func findPointingPair(constraints []constraint) (Step, bool) {
+	for _, box := range masks[2] {
+		for digit := 1; digit <= dim; digit++ {
+			var cells []int
+			for _, cell := range box {
+				if constraints[cell][digit] {
+					cells = append(cells, cell)
+				}
+			}
+			if len(cells) < 2 || len(cells) > boxDim {
+				continue
+			}
+			if step, ok := eliminateAlongLine(constraints, cells, digit, masks[0], "Pointing Pair"); ok {
+				return step, true
+			}
+			if step, ok := eliminateAlongLine(constraints, cells, digit, masks[1], "Pointing Pair"); ok {
+				return step, true
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// findBoxLineReduction looks for a digit within a row or column confined
+// to a single box, and eliminates it from the rest of that box.
+func findBoxLineReduction(constraints []constraint) (Step, bool) {
+	for _, lineMasks := range [][][]int{masks[0], masks[1]} {
+		for _, line := range lineMasks {
+			for digit := 1; digit <= dim; digit++ {
+				var cells []int
+				for _, cell := range line {
+					if constraints[cell][digit] {
+						cells = append(cells, cell)
+					}
+				}
+				if len(cells) < 2 || len(cells) > boxDim {
+					continue
+				}
+				if step, ok := eliminateAlongLine(constraints, cells, digit, masks[2], "Box/Line Reduction"); ok {
+					return step, true
+				}
+			}
+		}
+	}
+	return Step{}, false
+}
+
+// eliminateAlongLine finds the line in lineMasks that fully contains cells
+// and removes digit from the rest of that line.
+func eliminateAlongLine(constraints []constraint, cells []int, digit int, lineMasks [][]int, name string) (Step, bool) {
+	for _, line := range lineMasks {
+		allIn := true
+		for _, cell := range cells {
+			if !containsInt(line, cell) {
+				allIn = false
+				break
+			}
+		}
+		if !allIn {
+			continue
+		}
+
+		eliminated := map[int][]int{}
+		for _, cell := range line {
+			if containsInt(cells, cell) || !constraints[cell][digit] {
+				continue
+			}
+			delete(constraints[cell], digit)
+			eliminated[digit] = append(eliminated[digit], cell)
+		}
+		if len(eliminated) == 0 {
+			continue
+		}
+		return Step{Technique: name, Cells: cells, Eliminated: eliminated}, true
+	}
+	return Step{}, false
+}
+
+// findFish returns a strategy that looks for n rows (or n columns) where a
+// digit's candidates fall in exactly the same n columns (or rows), and
+// eliminates that digit from those columns (or rows) elsewhere. n=2 is the
+// X-Wing, n=3 is the Swordfish.
+func findFish(n int, name string) func([]constraint) (Step, bool) {
+	return func(constraints []constraint) (Step, bool) {
+		if step, ok := findFishDirection(constraints, n, name, masks[0], masks[1]); ok {
+			return step, true
+		}
+		return findFishDirection(constraints, n, name, masks[1], masks[0])
+	}
+}
+
+// findFishDirection looks for n lines in primary whose candidates for some
+// digit fall in exactly n lines of secondary, then eliminates that digit
+// from those secondary lines outside the primary lines.
+func findFishDirection(constraints []constraint, n int, name string, primary, secondary [][]int) (Step, bool) {
+	for digit := 1; digit <= dim; digit++ {
+		var lines []int
+		lineCols := map[int][]int{}
+		for i, line := range primary {
+			var positions []int
+			for _, cell := range line {
+				if constraints[cell][digit] {
+					positions = append(positions, indexOfLine(secondary, cell))
+				}
+			}
+			if count := len(positions); count >= 2 && count <= n {
+				lines = append(lines, i)
+				lineCols[i] = positions
+			}
+		}
+
+		for _, combo := range combinations(lines, n) {
+			colUnion := map[int]bool{}
+			for _, i := range combo {
+				for _, col := range lineCols[i] {
+					colUnion[col] = true
+				}
+			}
+			if len(colUnion) != n {
+				continue
+			}
+
+			eliminated := map[int][]int{}
+			var cells []int
+			for col := range colUnion {
+				for _, cell := range secondary[col] {
+					if lineSetContains(primary, combo, cell) {
+						cells = append(cells, cell)
+						continue
+					}
+					if constraints[cell][digit] {
+						delete(constraints[cell], digit)
+						eliminated[digit] = append(eliminated[digit], cell)
+					}
+				}
+			}
+			if len(eliminated) == 0 {
+				continue
+			}
+			return Step{Technique: name, Cells: cells, Eliminated: eliminated}, true
+		}
+	}
+	return Step{}, false
+}
+
+// indexOfLine returns the index of the line in lineMasks that contains
+// cell.
+func indexOfLine(lineMasks [][]int, cell int) int {
+	for i, line := range lineMasks {
+		if containsInt(line, cell) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lineSetContains reports whether cell belongs to any of the lines in
+// lineMasks named by combo.
+func lineSetContains(lineMasks [][]int, combo []int, cell int) bool {
+	for _, i := range combo {
+		if containsInt(lineMasks[i], cell) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt reports whether val is present in slice.
+func containsInt(slice []int, val int) bool {
+	for _, v := range slice {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// combinations returns every n-element combination of items, preserving
+// their relative order.
+func combinations(items []int, n int) [][]int {
+	if n <= 0 || n > len(items) {
+		return nil
+	}
+	var result [][]int
+	var combo []int
+	var recurse func(start int)
+	recurse = func(start int) {
+		if len(combo) == n {
+			result = append(result, append([]int{}, combo...))
+			return
+		}
+		for i := start; i < len(items); i++ {
+			combo = append(combo, items[i])
+			recurse(i + 1)
+			combo = combo[:len(combo)-1]
+		}
+	}
+	recurse(0)
+	return result
+}
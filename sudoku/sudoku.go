@@ -1,13 +1,11 @@
 package sudoku
 
 import (
-	"bufio"
 	"bytes"
 	"errors"
 	"io"
 	"math"
 	"strconv"
-	"strings"
 )
 
 const dim = 9 //The dimensions of a puzzle
@@ -42,50 +40,23 @@ func newConstraint(val int) constraint {
 	return c
 }
 
+// classic9 is the standard 9x9 row/column/box ruleset, used to back the
+// fixed-size API below.
+var classic9 = NewClassic(dim)
+
 // SolvePuzzle Attempts to solve a sudoku.
 // Takes a puzzle as an int slice row by row with 0 representing an unknown value.
 // Returns a constraint list of the result of attempting to solve the puzzle and a bool indicating if the attempt to solve succeeded.
 func SolvePuzzle(puzzle []int) ([]int, bool) {
-	constraints := puzzle2Constraints(puzzle)
-	finalSet, solved := solveBySearch(constraints)
-	return constraints2Puzzle(finalSet), solved
+	return classic9.Solve(puzzle)
 }
 
-// ReadSudokus Takes sudokus in the Euler 96 text format https://projecteuler.net/problem=96
-// Returns a 2D slice containing the parsed puzzles
+// ReadSudokus reads sudokus from reader, auto-detecting whether it holds
+// the Project Euler 96 format, SDM, SDK, plain 81-char strings, or JSON.
+// Returns a 2D slice containing the parsed puzzles. See Decoder for
+// reading a known format directly.
 func ReadSudokus(reader io.Reader) ([][]int, error) {
-	puzzles := make([][]int, 50)
-
-	scanner := bufio.NewScanner(reader)
-	numberCounter := 0
-	puzzleCounter := 0
-	for scanner.Scan() {
-		if strings.Contains(scanner.Text(), "Grid") {
-			continue
-		}
-		for _, runeValue := range scanner.Text() {
-			val := int(runeValue - '0')
-			if val > 9 || val < 0 {
-				return nil, errors.New("Invalid Character in puzzle: " + strconv.Itoa(puzzleCounter) + " element " + strconv.Itoa(numberCounter))
-			}
-			puzzles[puzzleCounter] = append(puzzles[puzzleCounter], val)
-			numberCounter++
-		}
-
-		if numberCounter == dim*dim {
-			puzzleCounter++
-			numberCounter = 0
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	if numberCounter != 0 {
-		return nil, errors.New("Invalid puzzle dimensions")
-	}
-	return puzzles, nil
+	return new(Decoder).Decode(reader)
 }
 
 // Attempts to solve a sudoku first by propagating constraints and then by brute force search
@@ -289,27 +260,5 @@ func generateBoardMasks() [][][]int {
 
 // ValidatePuzzle determines if a given puzzle is valid
 func ValidatePuzzle(puzzle []int) bool {
-	if len(puzzle) != dim*dim {
-		return false
-	}
-	for _, maskType := range masks {
-		for _, mask := range maskType {
-			if !validateMask(puzzle, mask) {
-				return false
-			}
-		}
-	}
-	return true
-}
-
-// Checks for duplicate numbers within a given masked off section of the puzzle
-func validateMask(puzzle []int, mask []int) bool {
-	seenBefore := make([]bool, dim+1)
-	for _, maskVal := range mask {
-		if puzzle[maskVal] != 0 && seenBefore[puzzle[maskVal]] == true {
-			return false
-		}
-		seenBefore[puzzle[maskVal]] = true
-	}
-	return true
+	return classic9.Validate(puzzle)
 }
@@ -1,8 +1,10 @@
 package sudoku_test
 
 import (
+	"bytes"
 	"log"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/monquixote/gosudoku/sudoku"
@@ -88,6 +90,120 @@ func TestSolvePuzzle(t *testing.T) {
 	}
 }
 
+func TestSolveWithStrategies(t *testing.T) {
+	unsolvedPuzzles := loadTestFile("valid_unsolved.txt")
+	solvedPuzzles := loadTestFile("valid_solved.txt")
+
+	for i, puzzle := range unsolvedPuzzles {
+		candidate, _, _, complete := sudoku.SolveWithStrategies(puzzle)
+		if !complete {
+			t.Errorf("Puzzle %v was not solved ", i)
+		}
+		for j, val := range candidate {
+			if val != solvedPuzzles[i][j] {
+				t.Errorf("Puzzle %v element %v does not match", i, j)
+			}
+		}
+	}
+}
+
+func TestSolvePuzzleFast(t *testing.T) {
+	unsolvedPuzzles := loadTestFile("valid_unsolved.txt")
+	solvedPuzzles := loadTestFile("valid_solved.txt")
+
+	for i, puzzle := range unsolvedPuzzles {
+		candidate, complete := sudoku.SolvePuzzleFast(puzzle)
+		if !complete {
+			t.Errorf("Puzzle %v was not solved ", i)
+		}
+		for j, val := range candidate {
+			if val != solvedPuzzles[i][j] {
+				t.Errorf("Puzzle %v element %v does not match", i, j)
+			}
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	puzzle, err := sudoku.Generate(42, sudoku.Medium)
+	if err != nil {
+		t.Fatalf("Generate returned an error: %v", err)
+	}
+	if !sudoku.ValidatePuzzle(puzzle) {
+		t.Errorf("Generated puzzle did not validate")
+	}
+	if got := sudoku.CountSolutions(puzzle, 2); got != 1 {
+		t.Errorf("Generated puzzle had %v solutions, want 1", got)
+	}
+	if _, _, rating, ok := sudoku.SolveWithStrategies(puzzle); !ok || rating > sudoku.Medium {
+		t.Errorf("Generated puzzle rated %v (solved=%v), want at most Medium", rating, ok)
+	}
+}
+
+const sdmLine = "003020600900305001001806400008102900700000008006708200002609500800203009005010300"
+
+func TestDecodeSDMAndSDK(t *testing.T) {
+	sdmPuzzles, err := sudoku.NewDecoder(sudoku.SDM).Decode(strings.NewReader(sdmLine + "\n"))
+	if err != nil {
+		t.Fatalf("SDM decode failed: %v", err)
+	}
+	if len(sdmPuzzles) != 1 || len(sdmPuzzles[0]) != 81 {
+		t.Fatalf("SDM decode returned %v puzzles", len(sdmPuzzles))
+	}
+
+	var sdk strings.Builder
+	sdk.WriteString("#Puzzle 1\n")
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			val := sdmPuzzles[0][row*9+col]
+			if val == 0 {
+				sdk.WriteByte('.')
+			} else {
+				sdk.WriteByte(byte('0' + val))
+			}
+		}
+		sdk.WriteByte('\n')
+	}
+
+	sdkPuzzles, err := sudoku.NewDecoder(sudoku.SDK).Decode(strings.NewReader(sdk.String()))
+	if err != nil {
+		t.Fatalf("SDK decode failed: %v", err)
+	}
+	if len(sdkPuzzles) != 1 {
+		t.Fatalf("SDK decode returned %v puzzles", len(sdkPuzzles))
+	}
+	for i, val := range sdkPuzzles[0] {
+		if val != sdmPuzzles[0][i] {
+			t.Errorf("SDK puzzle element %v does not match SDM", i)
+		}
+	}
+}
+
+func TestDecodeJSONRoundTrip(t *testing.T) {
+	sdmPuzzles, err := sudoku.NewDecoder(sudoku.SDM).Decode(strings.NewReader(sdmLine + "\n"))
+	if err != nil {
+		t.Fatalf("SDM decode failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := sudoku.NewEncoder(sudoku.JSON).Encode(&buf, sdmPuzzles); err != nil {
+		t.Fatalf("JSON encode failed: %v", err)
+	}
+
+	jsonPuzzles, err := sudoku.NewDecoder(sudoku.JSON).Decode(&buf)
+	if err != nil {
+		t.Fatalf("JSON decode failed: %v", err)
+	}
+	if len(jsonPuzzles) != 1 {
+		t.Fatalf("JSON round-trip returned %v puzzles", len(jsonPuzzles))
+	}
+	for i, val := range jsonPuzzles[0] {
+		if val != sdmPuzzles[0][i] {
+			t.Errorf("JSON round-trip element %v does not match", i)
+		}
+	}
+}
+
 // Sequential Benchmark
 func BenchmarkSerial(b *testing.B) {
 	puzzles := loadTestFile("../../sudoku.txt")
@@ -114,3 +230,13 @@ func BenchmarkParallel(b *testing.B) {
 		<-bools
 	}
 }
+
+// Bitmask-based Benchmark
+func BenchmarkFast(b *testing.B) {
+	puzzles := loadTestFile("../../sudoku.txt")
+	b.ResetTimer()
+
+	for _, puzzle := range puzzles {
+		sudoku.SolvePuzzleFast(puzzle)
+	}
+}